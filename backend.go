@@ -0,0 +1,181 @@
+package pullem
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// BranchInfo describes a local branch for prune-policy evaluation.
+type BranchInfo struct {
+	Name string
+	// HasUpstream is true if the branch has an upstream tracking ref
+	// configured at all.
+	HasUpstream bool
+	// UpstreamGone is true if the branch has an upstream configured but
+	// that remote-tracking ref no longer exists (the remote branch was
+	// deleted).
+	UpstreamGone bool
+	// LastCommitUnix is the commit time of the branch tip.
+	LastCommitUnix int64
+}
+
+// Backend abstracts the git plumbing operations the walker needs, so the
+// same walk and policy logic can run against a shelled-out git binary or an
+// in-process implementation without any call site caring which one.
+type Backend interface {
+	// CurrentRef returns the repo's current position: if HEAD points at a
+	// branch, ref is that branch's name and detached is false; if HEAD is
+	// detached, ref is the checked-out commit hash and detached is true.
+	// It only errors when the repo itself can't be read.
+	CurrentRef(path string) (ref string, detached bool, err error)
+	// BranchExists reports whether a local branch named name exists.
+	BranchExists(path string, name string) (bool, error)
+	IsClean(path string) (bool, error)
+	Pull(path string, remote string, branch string) error
+	// Branches lists every local branch, for prune-policy evaluation.
+	Branches(path string) ([]BranchInfo, error)
+	// IsAncestor reports whether ancestor's tip is reachable from
+	// descendant, i.e. ancestor is merged into descendant.
+	IsAncestor(path string, ancestor string, descendant string) (bool, error)
+	DeleteBranch(path string, localRef string) error
+	// BranchHead returns the commit hash a local branch currently points
+	// at, for measuring how many commits a pull brought in.
+	BranchHead(path string, branch string) (string, error)
+	// CountCommits returns the number of commits on the from..to range,
+	// i.e. how many commits to has that from doesn't.
+	CountCommits(path string, from string, to string) (int, error)
+}
+
+func newBackend(name string) (Backend, error) {
+	switch name {
+	case "", "exec":
+		return execBackend{}, nil
+	case "gogit":
+		return gogitBackend{}, nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q", name)
+	}
+}
+
+// execBackend shells out to the git binary for every operation, exactly as
+// pullem always has.
+type execBackend struct{}
+
+func (execBackend) CurrentRef(path string) (string, bool, error) {
+	cmd := exec.Command("git", "symbolic-ref", "--short", "HEAD")
+	cmd.Dir = path
+	if outBytes, err := cmd.Output(); err == nil {
+		return strings.TrimSpace(string(outBytes)), false, nil
+	}
+
+	cmd = exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = path
+	outBytes, err := cmd.Output()
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSpace(string(outBytes)), true, nil
+}
+
+func (execBackend) BranchExists(path string, name string) (bool, error) {
+	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+name)
+	cmd.Dir = path
+	return cmd.Run() == nil, nil
+}
+
+func (execBackend) IsClean(path string) (bool, error) {
+	cmd := exec.Command("git", "status", "--porcelain")
+	cmd.Dir = path
+
+	outBytes, err := cmd.Output()
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(outBytes)) == "", nil
+}
+
+func (execBackend) Pull(path string, remote string, branch string) error {
+	cmd := exec.Command("git", "pull", remote, branch, "--ff-only")
+	cmd.Dir = path
+
+	return cmd.Run()
+}
+
+func (execBackend) Branches(path string) ([]BranchInfo, error) {
+	cmd := exec.Command("git", "for-each-ref",
+		"--format", "%(refname:short)\t%(upstream)\t%(upstream:track)\t%(committerdate:unix)",
+		"refs/heads")
+	cmd.Dir = path
+
+	outBytes, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	output := strings.TrimSpace(string(outBytes))
+	if output == "" {
+		return nil, nil
+	}
+
+	var infos []BranchInfo
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 {
+			continue
+		}
+
+		commitUnix, _ := strconv.ParseInt(fields[3], 10, 64)
+		infos = append(infos, BranchInfo{
+			Name:           fields[0],
+			HasUpstream:    fields[1] != "",
+			UpstreamGone:   fields[1] != "" && strings.Contains(fields[2], "[gone]"),
+			LastCommitUnix: commitUnix,
+		})
+	}
+
+	return infos, nil
+}
+
+func (execBackend) IsAncestor(path string, ancestor string, descendant string) (bool, error) {
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", ancestor, descendant)
+	cmd.Dir = path
+
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, err
+}
+
+func (execBackend) DeleteBranch(path string, localRef string) error {
+	cmd := exec.Command("git", "branch", "-D", localRef)
+	cmd.Dir = path
+
+	return cmd.Run()
+}
+
+func (execBackend) BranchHead(path string, branch string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "refs/heads/"+branch)
+	cmd.Dir = path
+
+	outBytes, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(outBytes)), nil
+}
+
+func (execBackend) CountCommits(path string, from string, to string) (int, error) {
+	cmd := exec.Command("git", "rev-list", "--count", from+".."+to)
+	cmd.Dir = path
+
+	outBytes, err := cmd.Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(outBytes)))
+}
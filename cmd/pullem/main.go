@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+
+	"github.com/uwedeportivo/pullem"
+)
+
+var cleanBranches = flag.Bool("prune", false, "if set prunes orphaned local branches using the gone,merged policy")
+var prunePolicyFlag = flag.String("prune-policy", "", "comma-separated prune policy: gone,merged,stale=<duration> (overrides -prune)")
+var dryRun = flag.Bool("dry-run", false, "print what would be pruned without deleting anything")
+var jobsFlag = flag.Int("jobs", runtime.NumCPU(), "number of repos to process concurrently")
+var yesToAll = flag.Bool("yes", false, "assume yes when confirming orphaned branch deletion (required for -jobs>1 prompts)")
+var configPath = flag.String("config", "", "path to a YAML config file declaring roots, include/exclude rules and per-repo overrides")
+var autoStash = flag.Bool("autostash", false, "stash dirty changes and switch to the default branch to pull, then restore the prior state")
+var backendFlag = flag.String("backend", "exec", "git backend to use: exec or gogit")
+var submodulesFlag = flag.Bool("submodules", true, "update submodules (--init --recursive) after a successful pull, for repos that declare .gitmodules")
+var outputFlag = flag.String("output", "text", "result format: text, json or ndjson (for CI pipelines)")
+
+// jsonRepoResult is the wire shape for -output=json/ndjson, matching the
+// fields a CI pipeline actually wants rather than pullem.RepoResult's
+// internal bookkeeping (Root, Path).
+type jsonRepoResult struct {
+	Repo          string   `json:"repo"`
+	Status        string   `json:"status"`
+	Reason        string   `json:"reason,omitempty"`
+	Pruned        []string `json:"pruned,omitempty"`
+	DurationMs    int64    `json:"duration_ms"`
+	PulledCommits int      `json:"pulledCommits"`
+}
+
+func toJSONResult(r pullem.RepoResult) jsonRepoResult {
+	return jsonRepoResult{
+		Repo:          r.Rel,
+		Status:        r.Status,
+		Reason:        r.Reason,
+		Pruned:        r.Pruned,
+		DurationMs:    r.DurationMs,
+		PulledCommits: r.PulledCommits,
+	}
+}
+
+func main() {
+	flag.Parse()
+
+	var pruneExplicit, prunePolicyExplicit, autoStashExplicit bool
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "prune":
+			pruneExplicit = true
+		case "prune-policy":
+			prunePolicyExplicit = true
+		case "autostash":
+			autoStashExplicit = true
+		}
+	})
+
+	if len(flag.Args()) > 1 {
+		fmt.Println(`
+Usage:
+    pullem
+         recursively updates git repos starting from current working dir)
+    pullem some_path
+         recursively updates git repos starting from specified path
+    pullem -config pullem.yaml
+         recursively updates git repos declared in the config file`)
+		os.Exit(0)
+	}
+
+	var cfg *pullem.Config
+	if *configPath != "" {
+		var err error
+		cfg, err = pullem.LoadConfig(*configPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var roots []string
+	switch {
+	case len(flag.Args()) == 1:
+		roots = []string{flag.Arg(0)}
+	case cfg != nil && len(cfg.Roots) > 0:
+		roots = cfg.Roots
+	default:
+		roots = []string{"."}
+	}
+
+	switch *outputFlag {
+	case "text", "json", "ndjson":
+	default:
+		log.Fatalf("unknown -output %q: must be text, json or ndjson", *outputFlag)
+	}
+	structured := *outputFlag != "text"
+
+	opts := pullem.Options{
+		Roots:      roots,
+		Config:     cfg,
+		Jobs:       *jobsFlag,
+		Backend:    *backendFlag,
+		DryRun:     *dryRun,
+		Submodules: *submodulesFlag,
+		AssumeYes:  *yesToAll,
+		// Interactive prompts would interleave with structured output on
+		// stdout, so json/ndjson runs never read a TTY; pass -yes if you
+		// also want pruning to actually happen.
+		AllowTTY: !structured,
+		OnResult: func(r pullem.RepoResult) {
+			switch *outputFlag {
+			case "ndjson":
+				line, err := json.Marshal(toJSONResult(r))
+				if err != nil {
+					log.Fatal(err)
+				}
+				fmt.Println(string(line))
+			case "json":
+				// buffered and printed once Run returns
+			default:
+				fmt.Print(r.Output)
+			}
+		},
+	}
+	switch {
+	case prunePolicyExplicit:
+		v := *prunePolicyFlag
+		opts.PruneOverride = &v
+	case pruneExplicit:
+		v := "off"
+		if *cleanBranches {
+			v = "gone,merged"
+		}
+		opts.PruneOverride = &v
+	}
+	if autoStashExplicit {
+		v := *autoStash
+		opts.AutoStashOverride = &v
+	}
+
+	report, err := pullem.Run(context.Background(), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch *outputFlag {
+	case "json":
+		repos := make([]jsonRepoResult, len(report.Results))
+		summary := map[string]int{}
+		for i, r := range report.Results {
+			repos[i] = toJSONResult(r)
+			summary[r.Status]++
+		}
+		out, err := json.Marshal(struct {
+			Repos   []jsonRepoResult `json:"repos"`
+			Summary map[string]int   `json:"summary"`
+		}{Repos: repos, Summary: summary})
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(out))
+	case "text":
+		if report.Partial {
+			fmt.Println("⚠️  one or more repos updated with a partial failure (see ❌ lines above)")
+		}
+	}
+
+	if report.Failed {
+		os.Exit(1)
+	}
+}
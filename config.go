@@ -0,0 +1,136 @@
+package pullem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultRemote and defaultPruneLevel are the policy values applied to a
+// repo when neither the config nor a path override says otherwise.
+const (
+	defaultRemote     = "origin"
+	defaultPruneLevel = "off"
+)
+
+var defaultBranchCandidates = []string{"main", "master", "trunk"}
+
+// Config is the root of a pullem YAML config file (-config path.yaml). It
+// declares the roots to walk, glob-based include/exclude rules, an ordered
+// list of default branch names (the first one that exists as a local branch
+// is taken as the repo's default branch), and per-path policy overrides.
+type Config struct {
+	Roots           []string       `yaml:"roots"`
+	Include         []string       `yaml:"include"`
+	Exclude         []string       `yaml:"exclude"`
+	DefaultBranches []string       `yaml:"defaultBranches"`
+	Overrides       []PathOverride `yaml:"overrides"`
+}
+
+// PathOverride customizes policy for repos whose relative path matches Path,
+// a doublestar glob such as "services/*" or "**/legacy/**".
+type PathOverride struct {
+	Path       string `yaml:"path"`
+	AllowDirty bool   `yaml:"allowDirty"`
+	AutoStash  bool   `yaml:"autoStash"`
+	// Remotes are tried in order; the first one that fast-forwards
+	// successfully wins, and later ones are only attempted if an earlier
+	// one fails (e.g. "origin" unreachable, falling back to "upstream").
+	Remotes []string `yaml:"remotes"`
+	// Prune is a comma-separated list of policy directives combined with
+	// AND semantics: "gone", "merged", "stale=<duration>" (e.g. "90d"),
+	// or "off" to disable pruning for matching repos.
+	Prune string `yaml:"prune"`
+}
+
+// Policy is the fully resolved set of behaviors pullem applies to a single
+// repo, after merging config defaults, the first matching path override,
+// and any CLI flags passed as overrides on top.
+type Policy struct {
+	AllowDirty bool
+	AutoStash  bool
+	// Remotes are tried in order until one pulls successfully.
+	Remotes         []string
+	Prune           string
+	DefaultBranches []string
+}
+
+// LoadConfig reads and parses a YAML config file from path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// included reports whether rel passes the config's include/exclude globs.
+// A path is included by default when no include patterns are given; an
+// exclude match always wins over an include match.
+func (c *Config) included(rel string) bool {
+	if c == nil {
+		return true
+	}
+
+	rel = filepath.ToSlash(rel)
+
+	for _, pattern := range c.Exclude {
+		if ok, _ := doublestar.Match(pattern, rel); ok {
+			return false
+		}
+	}
+
+	if len(c.Include) == 0 {
+		return true
+	}
+	for _, pattern := range c.Include {
+		if ok, _ := doublestar.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePolicy merges the config defaults with the first PathOverride
+// whose glob matches rel.
+func (c *Config) resolvePolicy(rel string) Policy {
+	policy := Policy{
+		Remotes:         []string{defaultRemote},
+		Prune:           defaultPruneLevel,
+		DefaultBranches: defaultBranchCandidates,
+	}
+
+	if c == nil {
+		return policy
+	}
+	if len(c.DefaultBranches) > 0 {
+		policy.DefaultBranches = c.DefaultBranches
+	}
+
+	rel = filepath.ToSlash(rel)
+	for _, o := range c.Overrides {
+		if ok, _ := doublestar.Match(o.Path, rel); !ok {
+			continue
+		}
+
+		policy.AllowDirty = o.AllowDirty
+		policy.AutoStash = o.AutoStash
+		if len(o.Remotes) > 0 {
+			policy.Remotes = o.Remotes
+		}
+		if o.Prune != "" {
+			policy.Prune = o.Prune
+		}
+		break
+	}
+
+	return policy
+}
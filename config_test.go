@@ -0,0 +1,173 @@
+package pullem
+
+import "testing"
+
+func TestConfigIncluded(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *Config
+		rel  string
+		want bool
+	}{
+		{name: "nil config includes everything", cfg: nil, rel: "anything", want: true},
+		{
+			name: "no include patterns includes everything not excluded",
+			cfg:  &Config{Exclude: []string{"**/vendor/**"}},
+			rel:  "services/api",
+			want: true,
+		},
+		{
+			name: "exclude match wins even without include patterns",
+			cfg:  &Config{Exclude: []string{"**/vendor/**"}},
+			rel:  "services/vendor/lib",
+			want: false,
+		},
+		{
+			name: "include match is required once include patterns are given",
+			cfg:  &Config{Include: []string{"services/*"}},
+			rel:  "tools/build",
+			want: false,
+		},
+		{
+			name: "include match passes",
+			cfg:  &Config{Include: []string{"services/*"}},
+			rel:  "services/api",
+			want: true,
+		},
+		{
+			name: "exclude wins over a matching include",
+			cfg:  &Config{Include: []string{"services/*"}, Exclude: []string{"services/legacy"}},
+			rel:  "services/legacy",
+			want: false,
+		},
+		{
+			name: "single-star exclude glob does not match nested paths",
+			cfg:  &Config{Exclude: []string{"third_party/*"}},
+			rel:  "third_party/foo/bar",
+			want: true,
+		},
+		{
+			name: "doublestar exclude glob matches any depth",
+			cfg:  &Config{Exclude: []string{"**/legacy/**"}},
+			rel:  "services/api/legacy/v1",
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.included(tt.rel); got != tt.want {
+				t.Fatalf("included(%q) = %v, want %v", tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigResolvePolicy(t *testing.T) {
+	t.Run("nil config returns the built-in defaults", func(t *testing.T) {
+		var cfg *Config
+		got := cfg.resolvePolicy("services/api")
+
+		want := Policy{
+			Remotes:         []string{defaultRemote},
+			Prune:           defaultPruneLevel,
+			DefaultBranches: defaultBranchCandidates,
+		}
+		if !policyEqual(got, want) {
+			t.Fatalf("resolvePolicy() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("config-level defaultBranches applies regardless of overrides", func(t *testing.T) {
+		cfg := &Config{DefaultBranches: []string{"develop"}}
+		got := cfg.resolvePolicy("services/api")
+
+		if len(got.DefaultBranches) != 1 || got.DefaultBranches[0] != "develop" {
+			t.Fatalf("resolvePolicy().DefaultBranches = %v, want [develop]", got.DefaultBranches)
+		}
+	})
+
+	t.Run("first matching override wins, later matches are ignored", func(t *testing.T) {
+		cfg := &Config{
+			Overrides: []PathOverride{
+				{Path: "services/*", AllowDirty: true, Prune: "gone"},
+				{Path: "services/api", AllowDirty: false, Prune: "gone,merged"},
+			},
+		}
+		got := cfg.resolvePolicy("services/api")
+
+		if !got.AllowDirty {
+			t.Fatalf("resolvePolicy().AllowDirty = false, want true (from the first matching override)")
+		}
+		if got.Prune != "gone" {
+			t.Fatalf("resolvePolicy().Prune = %q, want %q (from the first matching override)", got.Prune, "gone")
+		}
+	})
+
+	t.Run("an override only sets the fields it configures, the rest keep their defaults", func(t *testing.T) {
+		cfg := &Config{
+			Overrides: []PathOverride{
+				{Path: "services/*", AllowDirty: true},
+			},
+		}
+		got := cfg.resolvePolicy("services/api")
+
+		if !got.AllowDirty {
+			t.Fatalf("resolvePolicy().AllowDirty = false, want true")
+		}
+		if len(got.Remotes) != 1 || got.Remotes[0] != defaultRemote {
+			t.Fatalf("resolvePolicy().Remotes = %v, want [%s] (untouched by the override)", got.Remotes, defaultRemote)
+		}
+		if got.Prune != defaultPruneLevel {
+			t.Fatalf("resolvePolicy().Prune = %q, want %q (untouched by the override)", got.Prune, defaultPruneLevel)
+		}
+	})
+
+	t.Run("an override can replace remotes and prune together", func(t *testing.T) {
+		cfg := &Config{
+			Overrides: []PathOverride{
+				{Path: "services/*", Remotes: []string{"origin", "upstream"}, Prune: "stale=90d"},
+			},
+		}
+		got := cfg.resolvePolicy("services/api")
+
+		if len(got.Remotes) != 2 || got.Remotes[0] != "origin" || got.Remotes[1] != "upstream" {
+			t.Fatalf("resolvePolicy().Remotes = %v, want [origin upstream]", got.Remotes)
+		}
+		if got.Prune != "stale=90d" {
+			t.Fatalf("resolvePolicy().Prune = %q, want %q", got.Prune, "stale=90d")
+		}
+	})
+
+	t.Run("no override matches, defaults are untouched", func(t *testing.T) {
+		cfg := &Config{
+			Overrides: []PathOverride{
+				{Path: "tools/*", AllowDirty: true},
+			},
+		}
+		got := cfg.resolvePolicy("services/api")
+
+		if got.AllowDirty {
+			t.Fatalf("resolvePolicy().AllowDirty = true, want false (no override matched)")
+		}
+	})
+}
+
+func policyEqual(a, b Policy) bool {
+	if a.AllowDirty != b.AllowDirty || a.AutoStash != b.AutoStash || a.Prune != b.Prune {
+		return false
+	}
+	return stringSliceEqual(a.Remotes, b.Remotes) && stringSliceEqual(a.DefaultBranches, b.DefaultBranches)
+}
+
+func stringSliceEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
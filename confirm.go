@@ -0,0 +1,61 @@
+package pullem
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// confirmer serializes interactive prompts through a single stdin owner so
+// concurrent workers never race on os.Stdin. With more than one job running,
+// prompting is disabled unless the caller passed assumeYes: ask then just
+// returns false instead of blocking on a terminal several goroutines are
+// racing for.
+type confirmer struct {
+	mu        sync.Mutex
+	reader    *bufio.Reader
+	allowTTY  bool
+	assumeYes bool
+}
+
+func newConfirmer(allowTTY, assumeYes bool) *confirmer {
+	return &confirmer{
+		reader:    bufio.NewReader(os.Stdin),
+		allowTTY:  allowTTY,
+		assumeYes: assumeYes,
+	}
+}
+
+// ask prompts s and waits for a y/n answer, returning an error instead of
+// exiting the process if stdin can't be read (e.g. it's closed), so a
+// structured-output run never has a stray fatal log line land on stderr.
+func (c *confirmer) ask(s string) (bool, error) {
+	if c.assumeYes {
+		return true, nil
+	}
+	if !c.allowTTY {
+		return false, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for {
+		fmt.Printf("%s [y/n]: ", s)
+
+		response, err := c.reader.ReadString('\n')
+		if err != nil {
+			return false, err
+		}
+
+		response = strings.ToLower(strings.TrimSpace(response))
+
+		if response == "y" || response == "yes" {
+			return true, nil
+		} else if response == "n" || response == "no" {
+			return false, nil
+		}
+	}
+}
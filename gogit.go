@@ -0,0 +1,217 @@
+package pullem
+
+import (
+	"errors"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// gogitBackend performs every operation in-process against go-git rather
+// than shelling out, opening the repo once per call.
+type gogitBackend struct{}
+
+func (gogitBackend) open(path string) (*git.Repository, error) {
+	return git.PlainOpen(path)
+}
+
+func (b gogitBackend) CurrentRef(path string) (string, bool, error) {
+	repo, err := b.open(path)
+	if err != nil {
+		return "", false, err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", false, err
+	}
+	if !head.Name().IsBranch() {
+		return head.Hash().String(), true, nil
+	}
+	return head.Name().Short(), false, nil
+}
+
+func (b gogitBackend) BranchExists(path string, name string) (bool, error) {
+	repo, err := b.open(path)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(name), false)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (b gogitBackend) IsClean(path string) (bool, error) {
+	repo, err := b.open(path)
+	if err != nil {
+		return false, err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return false, err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return false, err
+	}
+	return status.IsClean(), nil
+}
+
+func (b gogitBackend) Pull(path string, remote string, branch string) error {
+	repo, err := b.open(path)
+	if err != nil {
+		return err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	err = wt.Pull(&git.PullOptions{
+		RemoteName:    remote,
+		ReferenceName: plumbing.NewBranchReferenceName(branch),
+		SingleBranch:  true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+	return nil
+}
+
+func (b gogitBackend) Branches(path string) ([]BranchInfo, error) {
+	repo, err := b.open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := repo.Branches()
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []BranchInfo
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+
+		info := BranchInfo{Name: name}
+
+		if commit, cerr := repo.CommitObject(ref.Hash()); cerr == nil {
+			info.LastCommitUnix = commit.Committer.When.Unix()
+		}
+
+		if branchCfg, cfgErr := repo.Branch(name); cfgErr == nil && branchCfg.Remote != "" && branchCfg.Merge != "" {
+			info.HasUpstream = true
+			remoteRef := plumbing.NewRemoteReferenceName(branchCfg.Remote, branchCfg.Merge.Short())
+			if _, rerr := repo.Reference(remoteRef, true); errors.Is(rerr, plumbing.ErrReferenceNotFound) {
+				info.UpstreamGone = true
+			}
+		}
+
+		infos = append(infos, info)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+func (b gogitBackend) IsAncestor(path string, ancestor string, descendant string) (bool, error) {
+	repo, err := b.open(path)
+	if err != nil {
+		return false, err
+	}
+
+	ancestorRef, err := repo.Reference(plumbing.NewBranchReferenceName(ancestor), true)
+	if err != nil {
+		return false, err
+	}
+	descendantRef, err := repo.Reference(plumbing.NewBranchReferenceName(descendant), true)
+	if err != nil {
+		return false, err
+	}
+
+	ancestorCommit, err := repo.CommitObject(ancestorRef.Hash())
+	if err != nil {
+		return false, err
+	}
+	descendantCommit, err := repo.CommitObject(descendantRef.Hash())
+	if err != nil {
+		return false, err
+	}
+
+	return ancestorCommit.IsAncestor(descendantCommit)
+}
+
+func (b gogitBackend) DeleteBranch(path string, localRef string) error {
+	repo, err := b.open(path)
+	if err != nil {
+		return err
+	}
+	return repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(localRef))
+}
+
+func (b gogitBackend) BranchHead(path string, branch string) (string, error) {
+	repo, err := b.open(path)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return "", err
+	}
+	return ref.Hash().String(), nil
+}
+
+func (b gogitBackend) CountCommits(path string, from string, to string) (int, error) {
+	repo, err := b.open(path)
+	if err != nil {
+		return 0, err
+	}
+
+	fromHash := plumbing.NewHash(from)
+	toHash := plumbing.NewHash(to)
+
+	fromCommits, err := repo.Log(&git.LogOptions{From: fromHash})
+	if err != nil {
+		return 0, err
+	}
+	defer fromCommits.Close()
+
+	reachable := map[plumbing.Hash]bool{}
+	err = fromCommits.ForEach(func(c *object.Commit) error {
+		reachable[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	commits, err := repo.Log(&git.LogOptions{From: toHash})
+	if err != nil {
+		return 0, err
+	}
+	defer commits.Close()
+
+	count := 0
+	err = commits.ForEach(func(c *object.Commit) error {
+		if !reachable[c.Hash] {
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,40 @@
+package pullem
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// hasLFS reports whether the repo at path uses Git LFS, going by the
+// presence of a .lfsconfig file or a "filter=lfs" entry in .gitattributes.
+func hasLFS(path string) bool {
+	if ok, _ := pathExists(filepath.Join(path, ".lfsconfig")); ok {
+		return true
+	}
+
+	data, err := os.ReadFile(filepath.Join(path, ".gitattributes"))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(data), "filter=lfs")
+}
+
+func pullLFS(path string) error {
+	cmd := exec.Command("git", "lfs", "pull")
+	cmd.Dir = path
+	return cmd.Run()
+}
+
+// hasSubmodules reports whether the repo at path declares submodules.
+func hasSubmodules(path string) bool {
+	ok, _ := pathExists(filepath.Join(path, ".gitmodules"))
+	return ok
+}
+
+func updateSubmodules(path string) error {
+	cmd := exec.Command("git", "submodule", "update", "--init", "--recursive")
+	cmd.Dir = path
+	return cmd.Run()
+}
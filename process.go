@@ -0,0 +1,273 @@
+package pullem
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// resolveDefaultBranch returns the repo's default branch: the first of
+// candidates that exists as a local branch. It deliberately never consults
+// the currently checked-out branch or HEAD, since the whole point of
+// resolving it is to compare it against whatever's actually checked out
+// (which may be a feature branch, or a detached HEAD).
+func resolveDefaultBranch(backend Backend, path string, candidates []string) (string, error) {
+	for _, candidate := range candidates {
+		if ok, err := backend.BranchExists(path, candidate); err == nil && ok {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("no default branch found among %v", candidates)
+}
+
+// pullFromRemotes tries each of remotes in order, returning as soon as one
+// fast-forwards successfully. It returns the last remote's error if all of
+// them fail.
+func pullFromRemotes(backend Backend, path string, remotes []string, branch string) error {
+	var err error
+	for _, remote := range remotes {
+		if err = backend.Pull(path, remote, branch); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func deleteBranch(backend Backend, path string, localRef string, confirm *confirmer) (bool, error) {
+	ok, err := confirm.ask(fmt.Sprintf("\tDo you really want to delete branch %s", localRef))
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, nil
+	}
+
+	return true, backend.DeleteBranch(path, localRef)
+}
+
+// autoStashPull handles the dirty-working-tree / off-default-branch case for
+// repos with AutoStash enabled. It stashes uncommitted changes (if any),
+// switches to branch (if the repo wasn't already on it), pulls, then always
+// switches back and restores the stash via a deferred rollback so the repo
+// ends up exactly where it started regardless of whether the pull succeeded.
+// originalRef is whatever `git checkout` accepts to get back to the prior
+// state: a branch name, or a commit hash if HEAD was detached. Stash and
+// branch switches always run through the git binary, since neither backend
+// implementation supports them in-process. A stash-pop conflict is reported
+// and left for manual recovery rather than treated as fatal.
+func autoStashPull(backend Backend, path string, originalRef string, branch string, remotes []string, dirty bool, out *strings.Builder) bool {
+	stashed := false
+	switched := originalRef != branch
+
+	if dirty {
+		stashMsg := fmt.Sprintf("pullem-autostash-%d", time.Now().Unix())
+		cmd := exec.Command("git", "stash", "push", "-u", "-m", stashMsg)
+		cmd.Dir = path
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(out, "\t❌ autostash: failed to stash changes %v\n", err)
+			return false
+		}
+		stashed = true
+		fmt.Fprintf(out, "\t↕️  autostash: stashed local changes as %q\n", stashMsg)
+	}
+
+	if switched {
+		cmd := exec.Command("git", "checkout", branch)
+		cmd.Dir = path
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(out, "\t❌ autostash: failed to switch to %s %v\n", branch, err)
+			if stashed {
+				popCmd := exec.Command("git", "stash", "pop")
+				popCmd.Dir = path
+				popCmd.Run()
+			}
+			return false
+		}
+		fmt.Fprintf(out, "\t↕️  autostash: switched from %s to %s\n", originalRef, branch)
+	}
+
+	ok := true
+	defer func() {
+		if switched {
+			cmd := exec.Command("git", "checkout", originalRef)
+			cmd.Dir = path
+			if err := cmd.Run(); err != nil {
+				fmt.Fprintf(out, "\t❌ autostash: failed to switch back to %s %v\n", originalRef, err)
+				ok = false
+			} else {
+				fmt.Fprintf(out, "\t↕️  autostash: switched back to %s\n", originalRef)
+			}
+		}
+		if stashed {
+			cmd := exec.Command("git", "stash", "pop")
+			cmd.Dir = path
+			if err := cmd.Run(); err != nil {
+				fmt.Fprintf(out, "\t⚠️  autostash: stash pop hit conflicts, leaving stash entry for manual recovery\n")
+			} else {
+				fmt.Fprintf(out, "\t↕️  autostash: restored stashed changes\n")
+			}
+		}
+	}()
+
+	if err := pullFromRemotes(backend, path, remotes, branch); err != nil {
+		fmt.Fprintf(out, "❌  fast forwarding not possible\n")
+		ok = false
+	}
+
+	return ok
+}
+
+// procOutcome is the structured result of processDir, carrying everything a
+// caller needs both for the human-readable Output text and for machine
+// consumers (the CLI's -output=json/ndjson modes).
+type procOutcome struct {
+	Output  string
+	Ok      bool
+	Partial bool
+	// Status is one of "updated", "skipped" or "failed".
+	Status string
+	// Reason explains a non-"updated" Status, e.g. "not_clean",
+	// "not_on_default", "ff_failed". Empty when Status is "updated".
+	Reason string
+	// Pruned lists the orphaned branches removed (or, under dry-run, that
+	// would have been removed).
+	Pruned        []string
+	DurationMs    int64
+	PulledCommits int
+}
+
+// processDir runs the branch-check -> clean-check -> pull -> prune pipeline
+// for a single repo. All output is buffered into a single string rather than
+// printed directly, so that the caller can flush it under a mutex and keep a
+// repo's lines together even when several repos are processed concurrently.
+func processDir(backend Backend, path string, rel string, confirm *confirmer, policy Policy, dryRun bool, submodules bool) procOutcome {
+	start := time.Now()
+	var out strings.Builder
+
+	fail := func(status, reason, format string, args ...interface{}) procOutcome {
+		fmt.Fprintf(&out, format, args...)
+		return procOutcome{Output: out.String(), Ok: status != "failed" && status != "skipped", Status: status, Reason: reason, DurationMs: time.Since(start).Milliseconds()}
+	}
+
+	branch, err := resolveDefaultBranch(backend, path, policy.DefaultBranches)
+	if err != nil {
+		return fail("failed", "resolve_default_branch_failed", "❌  %s failed processing %v\n", rel, err)
+	}
+
+	currentRef, detached, err := backend.CurrentRef(path)
+	if err != nil {
+		return fail("failed", "detect_branch_failed", "❌  %s failed processing %v\n", rel, err)
+	}
+	onDefault := !detached && currentRef == branch
+
+	clean, err := backend.IsClean(path)
+	if err != nil {
+		return fail("failed", "detect_clean_failed", "❌  %s failed processing %v\n", rel, err)
+	}
+
+	preHead, _ := backend.BranchHead(path, branch)
+
+	if !onDefault || (!clean && !policy.AllowDirty) {
+		if !policy.AutoStash {
+			if !onDefault {
+				return fail("skipped", "not_on_default", "❌  %s not on default branch\n", rel)
+			}
+			return fail("skipped", "not_clean", "❌  %s not clean\n", rel)
+		}
+
+		if !autoStashPull(backend, path, currentRef, branch, policy.Remotes, !clean, &out) {
+			return procOutcome{Output: out.String(), Ok: false, Status: "failed", Reason: "autostash_failed", DurationMs: time.Since(start).Milliseconds()}
+		}
+	} else if err := pullFromRemotes(backend, path, policy.Remotes, branch); err != nil {
+		return fail("failed", "ff_failed", "❌  %s fast forwarding not possible\n", rel)
+	}
+
+	fmt.Fprintf(&out, "✅  %s updated\n", rel)
+
+	pulledCommits := 0
+	if postHead, err := backend.BranchHead(path, branch); err == nil && preHead != "" && postHead != preHead {
+		if n, err := backend.CountCommits(path, preHead, postHead); err == nil {
+			pulledCommits = n
+		}
+	}
+
+	ok := true
+	partial := false
+
+	if hasLFS(path) {
+		if err := pullLFS(path); err != nil {
+			fmt.Fprintf(&out, "\t❌ git lfs pull failed %v\n", err)
+			partial = true
+		} else {
+			fmt.Fprintf(&out, "\t✅ git lfs pull\n")
+		}
+	}
+
+	if submodules && hasSubmodules(path) {
+		if err := updateSubmodules(path); err != nil {
+			fmt.Fprintf(&out, "\t❌ submodule update failed %v\n", err)
+			partial = true
+		} else {
+			fmt.Fprintf(&out, "\t✅ submodules updated\n")
+		}
+	}
+
+	prunePol, err := parsePrunePolicy(policy.Prune)
+	if err != nil {
+		fmt.Fprintf(&out, "\t❌ failed pruning orphaned branches %v\n", err)
+		return procOutcome{Output: out.String(), Ok: false, Partial: partial, Status: "updated", DurationMs: time.Since(start).Milliseconds(), PulledCommits: pulledCommits}
+	}
+
+	var pruned []string
+
+	if prunePol.active() {
+		branches, err := backend.Branches(path)
+		if err != nil {
+			fmt.Fprintf(&out, "\t❌ failed pruning orphaned branches %v\n", err)
+			return procOutcome{Output: out.String(), Ok: false, Partial: partial, Status: "updated", DurationMs: time.Since(start).Milliseconds(), PulledCommits: pulledCommits}
+		}
+
+		for _, info := range branches {
+			if info.Name == branch {
+				continue
+			}
+
+			eligible, err := prunePol.eligible(backend, path, info, branch)
+			if err != nil {
+				fmt.Fprintf(&out, "\t❌ failed evaluating prune policy for %s %v\n", info.Name, err)
+				ok = false
+				continue
+			}
+			if !eligible {
+				continue
+			}
+
+			if dryRun {
+				fmt.Fprintf(&out, "\t🔍 would prune orphaned branch %s\n", info.Name)
+				pruned = append(pruned, info.Name)
+				continue
+			}
+
+			deleted, err := deleteBranch(backend, path, info.Name, confirm)
+			if err != nil {
+				fmt.Fprintf(&out, "\t❌ failed pruning orphaned branch %s %v\n", info.Name, err)
+				ok = false
+			} else if deleted {
+				fmt.Fprintf(&out, "\t✅ pruned orphaned branch %s\n", info.Name)
+				pruned = append(pruned, info.Name)
+			}
+		}
+	}
+
+	return procOutcome{
+		Output:        out.String(),
+		Ok:            ok,
+		Partial:       partial,
+		Status:        "updated",
+		Pruned:        pruned,
+		DurationMs:    time.Since(start).Milliseconds(),
+		PulledCommits: pulledCommits,
+	}
+}
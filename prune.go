@@ -0,0 +1,89 @@
+package pullem
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// prunePolicy is the parsed form of a Policy.Prune string such as
+// "gone,merged,stale=90d". Directives combine with AND semantics: a branch
+// is eligible only if it satisfies every directive present.
+type prunePolicy struct {
+	gone       bool
+	merged     bool
+	staleAfter time.Duration
+}
+
+func (p prunePolicy) active() bool {
+	return p.gone || p.merged || p.staleAfter > 0
+}
+
+func parsePrunePolicy(s string) (prunePolicy, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "off" {
+		return prunePolicy{}, nil
+	}
+
+	var p prunePolicy
+	for _, directive := range strings.Split(s, ",") {
+		directive = strings.TrimSpace(directive)
+		switch {
+		case directive == "gone":
+			p.gone = true
+		case directive == "merged":
+			p.merged = true
+		case strings.HasPrefix(directive, "stale="):
+			d, err := parseStaleDuration(strings.TrimPrefix(directive, "stale="))
+			if err != nil {
+				return prunePolicy{}, fmt.Errorf("invalid prune policy %q: %w", s, err)
+			}
+			p.staleAfter = d
+		default:
+			return prunePolicy{}, fmt.Errorf("invalid prune policy %q: unknown directive %q", s, directive)
+		}
+	}
+	return p, nil
+}
+
+// parseStaleDuration parses durations like "90d" in addition to everything
+// time.ParseDuration already accepts, since git branch ages are naturally
+// expressed in days.
+func parseStaleDuration(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// eligible reports whether info should be pruned under p, given the
+// repo's default branch for merged-ness comparisons.
+func (p prunePolicy) eligible(backend Backend, path string, info BranchInfo, defaultBranch string) (bool, error) {
+	if p.gone && !info.UpstreamGone {
+		return false, nil
+	}
+
+	if p.merged {
+		merged, err := backend.IsAncestor(path, info.Name, defaultBranch)
+		if err != nil {
+			return false, err
+		}
+		if !merged {
+			return false, nil
+		}
+	}
+
+	if p.staleAfter > 0 {
+		age := time.Since(time.Unix(info.LastCommitUnix, 0))
+		if age <= p.staleAfter {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
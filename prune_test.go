@@ -0,0 +1,130 @@
+package pullem
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePrunePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		want    prunePolicy
+		wantErr bool
+	}{
+		{name: "empty is off", policy: "", want: prunePolicy{}},
+		{name: "off", policy: "off", want: prunePolicy{}},
+		{name: "gone", policy: "gone", want: prunePolicy{gone: true}},
+		{name: "merged", policy: "merged", want: prunePolicy{merged: true}},
+		{name: "gone,merged", policy: "gone,merged", want: prunePolicy{gone: true, merged: true}},
+		{name: "stale=90d", policy: "stale=90d", want: prunePolicy{staleAfter: 90 * 24 * time.Hour}},
+		{
+			name:   "gone,merged,stale=30d combine with AND semantics",
+			policy: "gone,merged,stale=30d",
+			want:   prunePolicy{gone: true, merged: true, staleAfter: 30 * 24 * time.Hour},
+		},
+		{name: "unknown directive", policy: "bogus", wantErr: true},
+		{name: "invalid stale duration", policy: "stale=not-a-duration", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePrunePolicy(tt.policy)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parsePrunePolicy(%q) = %v, want error", tt.policy, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePrunePolicy(%q) returned unexpected error: %v", tt.policy, err)
+			}
+			if got != tt.want {
+				t.Fatalf("parsePrunePolicy(%q) = %+v, want %+v", tt.policy, got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeAncestorBackend is a Backend stub that only implements IsAncestor
+// meaningfully, for exercising prunePolicy.eligible without real git
+// plumbing. Every other method is unused by eligible.
+type fakeAncestorBackend struct {
+	merged bool
+	err    error
+}
+
+func (fakeAncestorBackend) CurrentRef(string) (string, bool, error)   { return "", false, nil }
+func (fakeAncestorBackend) BranchExists(string, string) (bool, error) { return false, nil }
+func (fakeAncestorBackend) IsClean(string) (bool, error)              { return true, nil }
+func (fakeAncestorBackend) Pull(string, string, string) error         { return nil }
+func (fakeAncestorBackend) Branches(string) ([]BranchInfo, error)     { return nil, nil }
+func (fakeAncestorBackend) DeleteBranch(string, string) error         { return nil }
+func (fakeAncestorBackend) BranchHead(string, string) (string, error) { return "", nil }
+func (fakeAncestorBackend) CountCommits(string, string, string) (int, error) {
+	return 0, nil
+}
+func (b fakeAncestorBackend) IsAncestor(string, string, string) (bool, error) {
+	return b.merged, b.err
+}
+
+func TestPrunePolicyEligible(t *testing.T) {
+	now := time.Now()
+	backend := fakeAncestorBackend{merged: true}
+
+	tests := []struct {
+		name   string
+		policy prunePolicy
+		info   BranchInfo
+		want   bool
+	}{
+		{
+			name:   "gone directive rejects a branch with a live upstream",
+			policy: prunePolicy{gone: true},
+			info:   BranchInfo{UpstreamGone: false},
+			want:   false,
+		},
+		{
+			name:   "gone directive accepts a branch whose upstream is gone",
+			policy: prunePolicy{gone: true},
+			info:   BranchInfo{UpstreamGone: true},
+			want:   true,
+		},
+		{
+			name:   "merged directive accepts a branch reachable from default",
+			policy: prunePolicy{merged: true},
+			info:   BranchInfo{},
+			want:   true,
+		},
+		{
+			name:   "stale directive rejects a branch committed within the window",
+			policy: prunePolicy{staleAfter: 90 * 24 * time.Hour},
+			info:   BranchInfo{LastCommitUnix: now.Add(-24 * time.Hour).Unix()},
+			want:   false,
+		},
+		{
+			name:   "stale directive accepts a branch committed before the window",
+			policy: prunePolicy{staleAfter: 90 * 24 * time.Hour},
+			info:   BranchInfo{LastCommitUnix: now.Add(-100 * 24 * time.Hour).Unix()},
+			want:   true,
+		},
+		{
+			name:   "gone AND stale requires both to hold",
+			policy: prunePolicy{gone: true, staleAfter: 90 * 24 * time.Hour},
+			info:   BranchInfo{UpstreamGone: true, LastCommitUnix: now.Add(-24 * time.Hour).Unix()},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.policy.eligible(backend, "", tt.info, "main")
+			if err != nil {
+				t.Fatalf("eligible() returned unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("eligible() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
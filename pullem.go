@@ -0,0 +1,196 @@
+// Package pullem recursively discovers git repos under one or more roots
+// and brings each up to date: checks that it's on its default branch and
+// clean, fast-forwards it, and optionally prunes orphaned local branches.
+// It is importable as a library via Run, and also backs the pullem CLI in
+// cmd/pullem.
+package pullem
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+)
+
+// Options configures a Run. The zero value walks "." sequentially with the
+// exec backend, pruning and autostash both off.
+type Options struct {
+	// Roots to walk. Defaults to {"."} when empty.
+	Roots []string
+	// Config supplies include/exclude rules and per-path policy overrides.
+	// May be nil.
+	Config *Config
+	// Jobs is the number of repos processed concurrently. Values below 1
+	// are treated as 1.
+	Jobs int
+	// Backend selects the git implementation: "exec" (default) or "gogit".
+	Backend string
+	// PruneOverride, when non-nil, forces this prune policy (e.g.
+	// "gone,merged,stale=90d", or "off") for every repo regardless of
+	// what Config says.
+	PruneOverride *string
+	// AutoStashOverride, when non-nil, forces autostash on or off for
+	// every repo regardless of what Config says.
+	AutoStashOverride *bool
+	// DryRun prints what would be pruned without deleting anything or
+	// prompting for confirmation.
+	DryRun bool
+	// Submodules runs `git submodule update --init --recursive` after a
+	// successful pull for repos that declare a .gitmodules file.
+	Submodules bool
+	// AssumeYes answers yes to every orphaned-branch deletion prompt
+	// without reading stdin.
+	AssumeYes bool
+	// AllowTTY allows interactive prompting on stdin. It only takes
+	// effect when Jobs <= 1, since concurrent workers can't safely share
+	// a terminal prompt.
+	AllowTTY bool
+	// OnResult, if set, is invoked with each repo's result as soon as
+	// it's available, in addition to it being collected into the
+	// returned Report. Calls are serialized (never concurrent with each
+	// other), so a repo's output can be printed or accumulated without
+	// the callback doing its own locking.
+	OnResult func(RepoResult)
+}
+
+// RepoResult is the outcome of processing a single repo.
+type RepoResult struct {
+	Root string
+	Path string
+	Rel  string
+	Ok   bool
+	// Partial is true when the repo was pulled successfully but a
+	// best-effort follow-up step (LFS pull, submodule update) failed.
+	Partial bool
+	Output  string
+	// Status is one of "updated", "skipped" or "failed", for structured
+	// consumers that want a machine-checkable outcome instead of parsing
+	// Output.
+	Status string
+	// Reason explains a non-"updated" Status, e.g. "not_clean",
+	// "not_on_default", "ff_failed". Empty when Status is "updated".
+	Reason string
+	// Pruned lists the orphaned branches removed (or, under dry-run, that
+	// would have been removed).
+	Pruned []string
+	// DurationMs is how long processing this repo took.
+	DurationMs int64
+	// PulledCommits is how many commits the pull brought in.
+	PulledCommits int
+}
+
+// Report is the aggregate outcome of a Run.
+type Report struct {
+	Results []RepoResult
+	Failed  bool
+	// Partial is true if any repo in Results had Partial set.
+	Partial bool
+}
+
+func resolveRunPolicy(opts Options, rel string) Policy {
+	policy := opts.Config.resolvePolicy(rel)
+
+	if opts.PruneOverride != nil {
+		policy.Prune = *opts.PruneOverride
+	}
+	if opts.AutoStashOverride != nil {
+		policy.AutoStash = *opts.AutoStashOverride
+	}
+
+	return policy
+}
+
+// Run walks opts.Roots, updating every repo it finds according to opts and
+// the resolved policy for its path, and returns a Report summarizing the
+// outcome. It does not print anything itself; use Options.OnResult to
+// observe progress as repos complete.
+func Run(ctx context.Context, opts Options) (Report, error) {
+	backend, err := newBackend(opts.Backend)
+	if err != nil {
+		return Report{}, err
+	}
+
+	roots := opts.Roots
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	absRoots := make([]string, len(roots))
+	for i, root := range roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return Report{}, err
+		}
+		absRoots[i] = abs
+	}
+
+	numJobs := opts.Jobs
+	if numJobs < 1 {
+		numJobs = 1
+	}
+
+	confirm := newConfirmer(opts.AllowTTY && numJobs == 1, opts.AssumeYes)
+
+	candidates := make(chan candidate)
+	var walkErr error
+	go func() {
+		for _, root := range absRoots {
+			if err := discover(ctx, root, opts.Config, candidates); err != nil {
+				walkErr = err
+				break
+			}
+		}
+		close(candidates)
+	}()
+
+	var mu sync.Mutex
+	var report Report
+	var wg sync.WaitGroup
+
+	for i := 0; i < numJobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range candidates {
+				rel, err := filepath.Rel(c.root, c.path)
+				if err != nil {
+					rel = c.path
+				}
+
+				policy := resolveRunPolicy(opts, rel)
+				outcome := processDir(backend, c.path, rel, confirm, policy, opts.DryRun, opts.Submodules)
+				result := RepoResult{
+					Root:          c.root,
+					Path:          c.path,
+					Rel:           rel,
+					Ok:            outcome.Ok,
+					Partial:       outcome.Partial,
+					Output:        outcome.Output,
+					Status:        outcome.Status,
+					Reason:        outcome.Reason,
+					Pruned:        outcome.Pruned,
+					DurationMs:    outcome.DurationMs,
+					PulledCommits: outcome.PulledCommits,
+				}
+
+				mu.Lock()
+				report.Results = append(report.Results, result)
+				if !outcome.Ok {
+					report.Failed = true
+				}
+				if outcome.Partial {
+					report.Partial = true
+				}
+				if opts.OnResult != nil {
+					opts.OnResult(result)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if walkErr != nil {
+		return report, walkErr
+	}
+	return report, nil
+}
@@ -0,0 +1,63 @@
+package pullem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// candidate is a discovered repo together with the root it was found under,
+// so that its path relative to that root can be recovered for display and
+// for config policy resolution.
+type candidate struct {
+	root string
+	path string
+}
+
+func pathExists(path string) (bool, error) {
+	_, err := os.Lstat(path)
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// discover walks root and sends the path of every repo (a directory
+// containing a .git entry) onto candidates, skipping any whose path relative
+// to root is excluded by cfg. It does not descend into a repo's own working
+// tree, and it stops early once ctx is done.
+func discover(ctx context.Context, root string, cfg *Config, candidates chan<- candidate) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+
+		isGitDir, err := pathExists(filepath.Join(path, ".git"))
+		if err != nil {
+			return err
+		}
+		if !isGitDir {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		if !cfg.included(rel) {
+			return filepath.SkipDir
+		}
+
+		candidates <- candidate{root: root, path: path}
+		return filepath.SkipDir
+	})
+}